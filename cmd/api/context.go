@@ -0,0 +1,33 @@
+package main
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/6ill/greenlight/internal/data"
+)
+
+// userContextKey is the key we use to store and retrieve the authenticated user from
+// a request context.
+type contextKey string
+
+const userContextKey = contextKey("user")
+
+// contextSetUser returns a new copy of the request with the provided User struct added
+// to the context.
+func (app *Application) contextSetUser(r *http.Request, user *data.User) *http.Request {
+	ctx := context.WithValue(r.Context(), userContextKey, user)
+	return r.WithContext(ctx)
+}
+
+// contextGetUser retrieves the User struct from the request context. It panics if the
+// context holds no *data.User value at all. For a request that had no Authorization
+// header, the authenticate middleware stores a nil *data.User, so this returns nil.
+func (app *Application) contextGetUser(r *http.Request) *data.User {
+	user, ok := r.Context().Value(userContextKey).(*data.User)
+	if !ok {
+		panic("missing user value in request context")
+	}
+
+	return user
+}
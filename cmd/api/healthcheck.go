@@ -8,9 +8,9 @@ func (app *Application) healthcheckHandler(w http.ResponseWriter, r *http.Reques
 	// Create a map which holds the information that we want to send in the response.
 	data := Envelope{
 		"status":      "available",
-		"system_info": map[string]string {
+		"system_info": map[string]string{
 			"version":     version,
-			"environment": app.config.env,
+			"environment": app.config.Env(),
 		},
 	}
 
@@ -5,109 +5,96 @@ import (
 	"database/sql"
 	"errors"
 	"expvar"
-	"flag"
 	"fmt"
 	"os"
+	"os/signal"
 	"runtime"
-	"strings"
 	"sync"
+	"syscall"
 	"time"
 
+	"github.com/6ill/greenlight/internal/auth"
+	"github.com/6ill/greenlight/internal/config"
 	"github.com/6ill/greenlight/internal/data"
+	datadb "github.com/6ill/greenlight/internal/data/db"
 	"github.com/6ill/greenlight/internal/jsonlog"
 	"github.com/6ill/greenlight/internal/mailer"
+	"github.com/6ill/greenlight/internal/streaming"
 	"github.com/6ill/greenlight/internal/vcs"
 	"github.com/joho/godotenv"
 	_ "github.com/lib/pq"
 )
 
+// appName is used to identify this application as the issuer and audience of the JWTs
+// it mints.
+const appName = "greenlight"
+
 var (
 	version = vcs.Version()
 )
 
-// Define a config struct to hold all the configuration settings for our application.
-// For now, the only configuration settings will be the network port that we want the
-// server to listen on, and the name of the current operating environment for the
-// application (development, staging, production, etc.). We will read in these
-// configuration settings from command-line flags when the application starts.
-type Config struct {
-	port int
-	env  string
-	db   struct {
-		dsn          string
-		maxOpenConns int
-		maxIdleConns int
-		maxIdleTime  string
-	}
-	limiter struct {
-		rps     float64
-		burst   int
-		enabled bool
-	}
-	smtp struct {
-		host     string
-		port     int
-		username string
-		password string
-		sender   string
-	}
-	cors struct {
-		trustedOrigins []string
-	}
-}
-
 // Include a sync.WaitGroup in the application struct. The zero-value for a
 // sync.WaitGroup type is a valid, useable, sync.WaitGroup with a 'counter' value of 0,
 // so we don't need to do anything else to initialize it before we can use it.
 type Application struct {
-	config Config
-	logger *jsonlog.Logger
-	models data.Models
-	mailer mailer.Mailer
-	wg     sync.WaitGroup
+	config       *config.ConfigState
+	logger       *jsonlog.Logger
+	db           *datadb.DB
+	models       data.Models
+	mailerMu     sync.RWMutex
+	mailer       mailer.Mailer
+	auth         auth.Authenticator
+	streamHub    *streaming.Hub
+	shutdown     chan struct{}
+	shutdownOnce sync.Once
+	wg           sync.WaitGroup
+}
+
+// Shutdown signals every in-flight streaming connection to close, so that wg.Wait()
+// (called by Serve() once the HTTP server stops accepting new requests) isn't blocked
+// by otherwise-idle WebSocket connections that have no other reason to exit. It's safe
+// to call more than once.
+func (app *Application) Shutdown() {
+	app.shutdownOnce.Do(func() {
+		close(app.shutdown)
+	})
+}
+
+// Mailer returns the application's current Mailer. It's safe to call from any
+// goroutine, including concurrently with a SIGHUP-triggered rebuildMailer.
+func (app *Application) Mailer() mailer.Mailer {
+	app.mailerMu.RLock()
+	defer app.mailerMu.RUnlock()
+	return app.mailer
+}
+
+// rebuildMailer reconstructs the application's Mailer from the current SMTP
+// settings and atomically swaps it in, so that a SIGHUP reload of SMTP
+// credentials actually takes effect.
+func (app *Application) rebuildMailer() {
+	m := mailer.New(app.config.SMTPHost(), app.config.SMTPPort(), app.config.SMTPUsername(), app.config.SMTPPassword(), app.config.SMTPSender())
+
+	app.mailerMu.Lock()
+	app.mailer = m
+	app.mailerMu.Unlock()
 }
 
 func main() {
-	// Declare an instance of the config struct.
-	var cfg Config
 	logger := jsonlog.New(os.Stdout, jsonlog.LevelInfo)
 
 	err := godotenv.Load()
 	if err != nil {
 		logger.PrintFatal(errors.New("error loading .env file"), nil)
 	}
-	flag.IntVar(&cfg.port, "port", 4000, "API server port")
-	flag.StringVar(&cfg.env, "env", "development", "Environment (development|staging|production)")
-	flag.StringVar(&cfg.db.dsn, "db-dsn", os.Getenv("GREENLIGHT_DB_DSN"), "PostgreSQL DSN")
-	flag.IntVar(&cfg.db.maxOpenConns, "db-max-open-conns", 25, "PostgreSQL max open connections")
-	flag.IntVar(&cfg.db.maxIdleConns, "db-max-idle-conns", 25, "PostgreSQL max idle connections")
-	flag.StringVar(&cfg.db.maxIdleTime, "db-max-idle-time", "15m", "PostgreSQL max connection idle time")
-	flag.Float64Var(&cfg.limiter.rps, "limiter-rps", 2, "Rate limiter maximum requests per second")
-	flag.IntVar(&cfg.limiter.burst, "limiter-burst", 4, "Rate limiter maximum burst")
-	flag.BoolVar(&cfg.limiter.enabled, "limiter-enabled", true, "Enable rate limiter")
-	// Read the SMTP server configuration settings into the config struct, using the
-	// Mailtrap settings as the default values. IMPORTANT: If you're following along,
-	// make sure to replace the default values for smtp-username and smtp-password
-	// with your own Mailtrap credentials.
-	flag.StringVar(&cfg.smtp.host, "smtp-host", "smtp.mailtrap.io", "SMTP host")
-	flag.IntVar(&cfg.smtp.port, "smtp-port", 2525, "SMTP port")
-	flag.StringVar(&cfg.smtp.username, "smtp-username", os.Getenv("SMTP_USERNAME"), "SMTP username")
-	flag.StringVar(&cfg.smtp.password, "smtp-password", os.Getenv("SMTP_PASSWORD"), "SMTP password")
-	flag.StringVar(&cfg.smtp.sender, "smtp-sender", "Greenlight <no-reply@greenlight.billhensen.net>", "SMTP sender")
-
-	flag.Func("cors-trusted-origins", "Trusted CORS origins (space separated)", func(s string) error {
-		cfg.cors.trustedOrigins = strings.Fields(s)
-		return nil
-	})
-
-	// Create a new version boolean flag with the default value of false.
-	displayVersion := flag.Bool("version", false, "Display version and exit")
 
-	flag.Parse()
+	cfg, configFilePath, showVersion, err := config.Load()
+	if err != nil {
+		logger.PrintFatal(err, nil)
+	}
 
 	// If the version flag value is true, then print out the version number and
 	// immediately exit.
-	if *displayVersion {
+	if showVersion {
 		fmt.Printf("Version:\t%s\n", version)
 		os.Exit(0)
 	}
@@ -135,55 +122,117 @@ func main() {
 	expvar.Publish("goroutines", expvar.Func(func() any {
 		return runtime.NumGoroutine()
 	}))
-	// Publish the database connection pool statistics.
+	// Publish the database connection pool statistics, alongside the per-query
+	// metrics (in-flight queries, total queries, average latency, timeout count)
+	// tracked by the instrumented connection pool, nested under the same key so
+	// existing dashboards that read the pool stats keep working.
 	expvar.Publish("database", expvar.Func(func() any {
-		return db.Stats()
+		return struct {
+			sql.DBStats
+			Queries datadb.Metrics `json:"queries"`
+		}{
+			DBStats: db.Stats(),
+			Queries: db.Metrics(),
+		}
 	}))
 	// Publish the current Unix timestamp.
 	expvar.Publish("timestamp", expvar.Func(func() any {
 		return time.Now().Unix()
 	}))
 
+	queryTimeout, err := time.ParseDuration(cfg.DBQueryTimeout())
+	if err != nil {
+		logger.PrintFatal(err, nil)
+	}
+
 	// Declare an instance of the application struct, containing the config struct and
 	// the logger.
 	app := &Application{
-		config: cfg,
-		logger: logger,
-		models: data.NewModels(db),
-		mailer: mailer.New(cfg.smtp.host, cfg.smtp.port, cfg.smtp.username, cfg.smtp.password, cfg.smtp.sender),
+		config:    cfg,
+		logger:    logger,
+		db:        db,
+		models:    data.NewModels(db, queryTimeout),
+		auth:      auth.New([]byte(cfg.JWTSecret()), appName),
+		streamHub: streaming.NewHub(),
+		shutdown:  make(chan struct{}),
 	}
+	app.rebuildMailer()
+
+	app.watchForReload(configFilePath)
+
 	err = app.Serve()
 	if err != nil {
 		logger.PrintFatal(err, nil)
 	}
 }
 
-// The openDB() function returns a sql.DB connection pool.
-func openDB(cfg Config) (*sql.DB, error) {
-	// Use sql.Open() to create an empty connection pool, using the DSN from the config
-	// struct.
-	db, err := sql.Open("postgres", cfg.db.dsn)
+// watchForReload starts a background goroutine that re-reads configFilePath and
+// atomically swaps in its runtime-tunable settings (rate-limiter rps/burst, CORS
+// trusted origins, SMTP credentials, DB idle time) whenever the process receives
+// SIGHUP, without needing a restart. It's a no-op if no config file was given.
+func (app *Application) watchForReload(configFilePath string) {
+	if configFilePath == "" {
+		return
+	}
+
+	sighup := make(chan os.Signal, 1)
+	signal.Notify(sighup, syscall.SIGHUP)
+
+	go func() {
+		for range sighup {
+			if err := app.config.ReloadFromFile(configFilePath); err != nil {
+				app.logger.PrintError(err, nil)
+				continue
+			}
+			app.rebuildMailer()
+
+			maxIdleTime, err := time.ParseDuration(app.config.DBMaxIdleTime())
+			if err != nil {
+				app.logger.PrintError(err, nil)
+				continue
+			}
+			app.db.SetConnMaxIdleTime(maxIdleTime)
+
+			app.logger.PrintInfo("reloaded runtime-tunable settings", map[string]string{
+				"config_file": configFilePath,
+			})
+		}
+	}()
+}
+
+// The openDB() function returns an instrumented connection pool wrapping *sql.DB.
+func openDB(cfg *config.ConfigState) (*datadb.DB, error) {
+	// Use datadb.Open() to create an empty connection pool, using the DSN from the
+	// config struct.
+	db, err := datadb.Open("postgres", cfg.DBDSN())
 	if err != nil {
 		return nil, err
 	}
 
 	// Set the maximum number of open (in-use + idle) connections in the pool. Note that
 	// passing a value less than or equal to 0 will mean there is no limit.
-	db.SetMaxOpenConns(cfg.db.maxOpenConns)
+	db.SetMaxOpenConns(cfg.DBMaxOpenConns())
 
 	// Set the maximum number of idle connections in the pool. Again, passing a value
 	// less than or equal to 0 will mean there is no limit.
-	db.SetMaxIdleConns(cfg.db.maxIdleConns)
+	db.SetMaxIdleConns(cfg.DBMaxIdleConns())
 
 	// Use the time.ParseDuration() function to convert the idle timeout duration string
 	// to a time.Duration type.
-	duration, err := time.ParseDuration(cfg.db.maxIdleTime)
+	duration, err := time.ParseDuration(cfg.DBMaxIdleTime())
 	if err != nil {
 		return nil, err
 	}
 	// Set the maximum idle timeout.
 	db.SetConnMaxIdleTime(duration)
 
+	// Set the maximum lifetime a connection may be reused for; 0 means unlimited.
+	maxLifetime, err := time.ParseDuration(cfg.DBConnMaxLifetime())
+	if err != nil {
+		return nil, err
+	}
+	db.SetConnMaxLifetime(maxLifetime)
+
 	// Create a context with a 5-second timeout deadline.
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/6ill/greenlight/internal/streaming"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// streamSendBufferSize is the number of undelivered events we'll buffer per
+	// connection before we start dropping them for that subscriber.
+	streamSendBufferSize = 16
+	// streamPongWait is how long we'll wait for a pong reply before considering a
+	// connection dead.
+	streamPongWait = 60 * time.Second
+	// streamPingPeriod is how often we send a ping; it must be shorter than
+	// streamPongWait.
+	streamPingPeriod = (streamPongWait * 9) / 10
+)
+
+var streamUpgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+}
+
+// streamMoviesHandler upgrades the connection to a WebSocket and streams JSON-encoded
+// streaming.Event values whenever a movie is created, updated, or deleted. Browsers
+// can't set arbitrary headers on the WebSocket upgrade request, so the access token is
+// passed as the "access_token" query string parameter instead of an Authorization
+// header.
+func (app *Application) streamMoviesHandler(w http.ResponseWriter, r *http.Request) {
+	userID, err := app.auth.ParseToken(r.URL.Query().Get("access_token"))
+	if err != nil {
+		app.invalidAuthenticationTokenResponse(w, r)
+		return
+	}
+
+	if _, err := app.models.Users.Get(userID); err != nil {
+		app.invalidAuthenticationTokenResponse(w, r)
+		return
+	}
+
+	conn, err := streamUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		app.logger.PrintError(err, nil)
+		return
+	}
+
+	id, events := app.streamHub.Subscribe(streaming.Filter{}, streamSendBufferSize)
+
+	app.wg.Add(1)
+	go func() {
+		defer app.wg.Done()
+		defer app.streamHub.Unsubscribe(id)
+		defer conn.Close()
+
+		conn.SetReadDeadline(time.Now().Add(streamPongWait))
+		conn.SetPongHandler(func(string) error {
+			return conn.SetReadDeadline(time.Now().Add(streamPongWait))
+		})
+
+		// We don't expect the client to send anything; this goroutine's only job is to
+		// notice when the connection is closed or goes idle, so that we unsubscribe
+		// and stop writing to it.
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+
+	app.wg.Add(1)
+	go func() {
+		defer app.wg.Done()
+		defer app.streamHub.Unsubscribe(id)
+		defer conn.Close()
+
+		ticker := time.NewTicker(streamPingPeriod)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case event, ok := <-events:
+				if !ok {
+					return
+				}
+
+				conn.SetWriteDeadline(time.Now().Add(streamPongWait))
+				if err := conn.WriteJSON(event); err != nil {
+					return
+				}
+			case <-ticker.C:
+				conn.SetWriteDeadline(time.Now().Add(streamPongWait))
+				if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+					return
+				}
+			case <-app.shutdown:
+				// The server is shutting down: closing the connection unblocks the
+				// reader goroutine's pending NextReader call so it can exit too,
+				// letting wg.Wait() return instead of waiting on an otherwise-idle
+				// client forever.
+				return
+			}
+		}
+	}()
+}
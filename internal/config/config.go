@@ -0,0 +1,341 @@
+// Package config centralizes the application's configuration: built-in defaults, an
+// optional YAML/JSON config file, environment variables, and command-line flags, in
+// that increasing order of precedence. The resulting ConfigState can also have a
+// subset of its settings ("runtime-tunable" ones) swapped in atomically while the
+// application is running, which is what powers SIGHUP-triggered reloads.
+package config
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Settings holds every tunable setting for the application.
+type Settings struct {
+	Port int
+	Env  string
+	DB   struct {
+		DSN             string
+		MaxOpenConns    int
+		MaxIdleConns    int
+		MaxIdleTime     string
+		QueryTimeout    string
+		ConnMaxLifetime string
+	}
+	Limiter struct {
+		RPS     float64
+		Burst   int
+		Enabled bool
+	}
+	SMTP struct {
+		Host     string
+		Port     int
+		Username string
+		Password string
+		Sender   string
+	}
+	CORS struct {
+		TrustedOrigins []string
+	}
+	JWT struct {
+		Secret string
+	}
+}
+
+// Defaults returns the baseline Settings used before any file, environment variable,
+// or command-line flag is applied. These match the hardcoded defaults the application
+// has always used.
+func Defaults() Settings {
+	var s Settings
+	s.Port = 4000
+	s.Env = "development"
+	s.DB.MaxOpenConns = 25
+	s.DB.MaxIdleConns = 25
+	s.DB.MaxIdleTime = "15m"
+	s.DB.QueryTimeout = "3s"
+	s.DB.ConnMaxLifetime = "0"
+	s.Limiter.RPS = 2
+	s.Limiter.Burst = 4
+	s.Limiter.Enabled = true
+	s.SMTP.Host = "smtp.mailtrap.io"
+	s.SMTP.Port = 2525
+	s.SMTP.Sender = "Greenlight <no-reply@greenlight.billhensen.net>"
+	return s
+}
+
+// runtimeTunable lists the dotted setting keys that a SIGHUP reload (or a call to
+// Set) is allowed to change. Everything else - the listen port, the environment name,
+// the database DSN and pool sizes, the JWT secret - requires a restart, since changing
+// them underneath already-running code would be unsafe or meaningless.
+var runtimeTunable = map[string]bool{
+	"limiter.rps":         true,
+	"limiter.burst":       true,
+	"cors.trustedOrigins": true,
+	"smtp.host":           true,
+	"smtp.port":           true,
+	"smtp.username":       true,
+	"smtp.password":       true,
+	"smtp.sender":         true,
+	"db.maxIdleTime":      true,
+}
+
+// ConfigState wraps a Settings value behind a mutex so that it can be read from
+// request-handling goroutines while being swapped atomically by a SIGHUP reload.
+type ConfigState struct {
+	mu       sync.RWMutex
+	settings Settings
+}
+
+// NewState returns a ConfigState seeded with the given Settings.
+func NewState(s Settings) *ConfigState {
+	return &ConfigState{settings: s}
+}
+
+// Snapshot returns a copy of the current settings.
+func (c *ConfigState) Snapshot() Settings {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.settings
+}
+
+func (c *ConfigState) Port() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.settings.Port
+}
+
+func (c *ConfigState) Env() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.settings.Env
+}
+
+func (c *ConfigState) DBDSN() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.settings.DB.DSN
+}
+
+func (c *ConfigState) DBMaxOpenConns() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.settings.DB.MaxOpenConns
+}
+
+func (c *ConfigState) DBMaxIdleConns() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.settings.DB.MaxIdleConns
+}
+
+func (c *ConfigState) DBMaxIdleTime() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.settings.DB.MaxIdleTime
+}
+
+func (c *ConfigState) DBQueryTimeout() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.settings.DB.QueryTimeout
+}
+
+func (c *ConfigState) DBConnMaxLifetime() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.settings.DB.ConnMaxLifetime
+}
+
+func (c *ConfigState) LimiterRPS() float64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.settings.Limiter.RPS
+}
+
+func (c *ConfigState) LimiterBurst() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.settings.Limiter.Burst
+}
+
+func (c *ConfigState) LimiterEnabled() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.settings.Limiter.Enabled
+}
+
+func (c *ConfigState) SMTPHost() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.settings.SMTP.Host
+}
+
+func (c *ConfigState) SMTPPort() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.settings.SMTP.Port
+}
+
+func (c *ConfigState) SMTPUsername() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.settings.SMTP.Username
+}
+
+func (c *ConfigState) SMTPPassword() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.settings.SMTP.Password
+}
+
+func (c *ConfigState) SMTPSender() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.settings.SMTP.Sender
+}
+
+func (c *ConfigState) CORSTrustedOrigins() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.settings.CORS.TrustedOrigins
+}
+
+func (c *ConfigState) JWTSecret() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.settings.JWT.Secret
+}
+
+// Get returns the current value of a dotted setting key (e.g. "db.maxOpenConns"), or
+// an error if the key is unrecognized.
+func (c *ConfigState) Get(key string) (any, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	switch key {
+	case "port":
+		return c.settings.Port, nil
+	case "env":
+		return c.settings.Env, nil
+	case "db.dsn":
+		return c.settings.DB.DSN, nil
+	case "db.maxOpenConns":
+		return c.settings.DB.MaxOpenConns, nil
+	case "db.maxIdleConns":
+		return c.settings.DB.MaxIdleConns, nil
+	case "db.maxIdleTime":
+		return c.settings.DB.MaxIdleTime, nil
+	case "db.queryTimeout":
+		return c.settings.DB.QueryTimeout, nil
+	case "db.connMaxLifetime":
+		return c.settings.DB.ConnMaxLifetime, nil
+	case "limiter.rps":
+		return c.settings.Limiter.RPS, nil
+	case "limiter.burst":
+		return c.settings.Limiter.Burst, nil
+	case "limiter.enabled":
+		return c.settings.Limiter.Enabled, nil
+	case "smtp.host":
+		return c.settings.SMTP.Host, nil
+	case "smtp.port":
+		return c.settings.SMTP.Port, nil
+	case "smtp.username":
+		return c.settings.SMTP.Username, nil
+	case "smtp.password":
+		return c.settings.SMTP.Password, nil
+	case "smtp.sender":
+		return c.settings.SMTP.Sender, nil
+	case "cors.trustedOrigins":
+		return c.settings.CORS.TrustedOrigins, nil
+	case "jwt.secret":
+		return c.settings.JWT.Secret, nil
+	default:
+		return nil, fmt.Errorf("config: unknown key %q", key)
+	}
+}
+
+// Set updates a single runtime-tunable setting. It returns an error if key doesn't
+// name a runtime-tunable setting, or if value isn't assignable to that setting's type.
+func (c *ConfigState) Set(key string, value any) error {
+	if !runtimeTunable[key] {
+		return fmt.Errorf("config: %q is not a runtime-tunable setting", key)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	switch key {
+	case "limiter.rps":
+		v, ok := value.(float64)
+		if !ok {
+			return fmt.Errorf("config: %q must be a float64", key)
+		}
+		c.settings.Limiter.RPS = v
+	case "limiter.burst":
+		v, ok := value.(int)
+		if !ok {
+			return fmt.Errorf("config: %q must be an int", key)
+		}
+		c.settings.Limiter.Burst = v
+	case "cors.trustedOrigins":
+		v, ok := value.([]string)
+		if !ok {
+			return fmt.Errorf("config: %q must be a []string", key)
+		}
+		c.settings.CORS.TrustedOrigins = v
+	case "smtp.host":
+		v, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("config: %q must be a string", key)
+		}
+		c.settings.SMTP.Host = v
+	case "smtp.port":
+		v, ok := value.(int)
+		if !ok {
+			return fmt.Errorf("config: %q must be an int", key)
+		}
+		c.settings.SMTP.Port = v
+	case "smtp.username":
+		v, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("config: %q must be a string", key)
+		}
+		c.settings.SMTP.Username = v
+	case "smtp.password":
+		v, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("config: %q must be a string", key)
+		}
+		c.settings.SMTP.Password = v
+	case "smtp.sender":
+		v, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("config: %q must be a string", key)
+		}
+		c.settings.SMTP.Sender = v
+	case "db.maxIdleTime":
+		v, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("config: %q must be a string", key)
+		}
+		c.settings.DB.MaxIdleTime = v
+	}
+
+	return nil
+}
+
+// ReloadFromFile re-reads the config file at path and atomically swaps in the
+// runtime-tunable settings it contains, leaving everything else (and anything the file
+// doesn't mention) untouched. It's intended to be called from a SIGHUP handler.
+func (c *ConfigState) ReloadFromFile(path string) error {
+	fs, err := loadFile(path)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	fs.applyRuntimeTunableTo(&c.settings)
+
+	return nil
+}
@@ -0,0 +1,170 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// fileSettings mirrors Settings but with pointer fields, so that a partial YAML/JSON
+// document only overrides the keys it actually mentions.
+type fileSettings struct {
+	Port *int    `yaml:"port" json:"port"`
+	Env  *string `yaml:"env" json:"env"`
+	DB   *struct {
+		DSN             *string `yaml:"dsn" json:"dsn"`
+		MaxOpenConns    *int    `yaml:"maxOpenConns" json:"maxOpenConns"`
+		MaxIdleConns    *int    `yaml:"maxIdleConns" json:"maxIdleConns"`
+		MaxIdleTime     *string `yaml:"maxIdleTime" json:"maxIdleTime"`
+		QueryTimeout    *string `yaml:"queryTimeout" json:"queryTimeout"`
+		ConnMaxLifetime *string `yaml:"connMaxLifetime" json:"connMaxLifetime"`
+	} `yaml:"db" json:"db"`
+	Limiter *struct {
+		RPS     *float64 `yaml:"rps" json:"rps"`
+		Burst   *int     `yaml:"burst" json:"burst"`
+		Enabled *bool    `yaml:"enabled" json:"enabled"`
+	} `yaml:"limiter" json:"limiter"`
+	SMTP *struct {
+		Host     *string `yaml:"host" json:"host"`
+		Port     *int    `yaml:"port" json:"port"`
+		Username *string `yaml:"username" json:"username"`
+		Password *string `yaml:"password" json:"password"`
+		Sender   *string `yaml:"sender" json:"sender"`
+	} `yaml:"smtp" json:"smtp"`
+	CORS *struct {
+		TrustedOrigins []string `yaml:"trustedOrigins" json:"trustedOrigins"`
+	} `yaml:"cors" json:"cors"`
+	JWT *struct {
+		Secret *string `yaml:"secret" json:"secret"`
+	} `yaml:"jwt" json:"jwt"`
+}
+
+// loadFile reads and decodes the config file at path. The format is chosen from the
+// file extension: ".json" for JSON, ".yaml"/".yml" for YAML.
+func loadFile(path string) (fileSettings, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fileSettings{}, err
+	}
+
+	var fs fileSettings
+
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".json":
+		err = json.Unmarshal(data, &fs)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &fs)
+	default:
+		return fileSettings{}, fmt.Errorf("config: unsupported config file extension %q", ext)
+	}
+	if err != nil {
+		return fileSettings{}, fmt.Errorf("config: parsing %s: %w", path, err)
+	}
+
+	return fs, nil
+}
+
+// applyTo overlays every key present in the file onto s.
+func (fs fileSettings) applyTo(s *Settings) {
+	if fs.Port != nil {
+		s.Port = *fs.Port
+	}
+	if fs.Env != nil {
+		s.Env = *fs.Env
+	}
+	if fs.DB != nil {
+		if fs.DB.DSN != nil {
+			s.DB.DSN = *fs.DB.DSN
+		}
+		if fs.DB.MaxOpenConns != nil {
+			s.DB.MaxOpenConns = *fs.DB.MaxOpenConns
+		}
+		if fs.DB.MaxIdleConns != nil {
+			s.DB.MaxIdleConns = *fs.DB.MaxIdleConns
+		}
+		if fs.DB.MaxIdleTime != nil {
+			s.DB.MaxIdleTime = *fs.DB.MaxIdleTime
+		}
+		if fs.DB.QueryTimeout != nil {
+			s.DB.QueryTimeout = *fs.DB.QueryTimeout
+		}
+		if fs.DB.ConnMaxLifetime != nil {
+			s.DB.ConnMaxLifetime = *fs.DB.ConnMaxLifetime
+		}
+	}
+	if fs.Limiter != nil {
+		if fs.Limiter.RPS != nil {
+			s.Limiter.RPS = *fs.Limiter.RPS
+		}
+		if fs.Limiter.Burst != nil {
+			s.Limiter.Burst = *fs.Limiter.Burst
+		}
+		if fs.Limiter.Enabled != nil {
+			s.Limiter.Enabled = *fs.Limiter.Enabled
+		}
+	}
+	if fs.SMTP != nil {
+		if fs.SMTP.Host != nil {
+			s.SMTP.Host = *fs.SMTP.Host
+		}
+		if fs.SMTP.Port != nil {
+			s.SMTP.Port = *fs.SMTP.Port
+		}
+		if fs.SMTP.Username != nil {
+			s.SMTP.Username = *fs.SMTP.Username
+		}
+		if fs.SMTP.Password != nil {
+			s.SMTP.Password = *fs.SMTP.Password
+		}
+		if fs.SMTP.Sender != nil {
+			s.SMTP.Sender = *fs.SMTP.Sender
+		}
+	}
+	if fs.CORS != nil && fs.CORS.TrustedOrigins != nil {
+		s.CORS.TrustedOrigins = fs.CORS.TrustedOrigins
+	}
+	if fs.JWT != nil && fs.JWT.Secret != nil {
+		s.JWT.Secret = *fs.JWT.Secret
+	}
+}
+
+// applyRuntimeTunableTo overlays only the runtime-tunable keys present in the file
+// onto s; it's used by ReloadFromFile so a SIGHUP can't change settings like the
+// listen port or DSN that aren't safe to swap underneath a running process.
+func (fs fileSettings) applyRuntimeTunableTo(s *Settings) {
+	if fs.Limiter != nil {
+		if fs.Limiter.RPS != nil {
+			s.Limiter.RPS = *fs.Limiter.RPS
+		}
+		if fs.Limiter.Burst != nil {
+			s.Limiter.Burst = *fs.Limiter.Burst
+		}
+	}
+	if fs.SMTP != nil {
+		if fs.SMTP.Host != nil {
+			s.SMTP.Host = *fs.SMTP.Host
+		}
+		if fs.SMTP.Port != nil {
+			s.SMTP.Port = *fs.SMTP.Port
+		}
+		if fs.SMTP.Username != nil {
+			s.SMTP.Username = *fs.SMTP.Username
+		}
+		if fs.SMTP.Password != nil {
+			s.SMTP.Password = *fs.SMTP.Password
+		}
+		if fs.SMTP.Sender != nil {
+			s.SMTP.Sender = *fs.SMTP.Sender
+		}
+	}
+	if fs.CORS != nil && fs.CORS.TrustedOrigins != nil {
+		s.CORS.TrustedOrigins = fs.CORS.TrustedOrigins
+	}
+	if fs.DB != nil && fs.DB.MaxIdleTime != nil {
+		s.DB.MaxIdleTime = *fs.DB.MaxIdleTime
+	}
+}
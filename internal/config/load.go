@@ -0,0 +1,155 @@
+package config
+
+import (
+	"flag"
+	"os"
+	"strings"
+)
+
+// Load builds the application's Settings by layering, from lowest to highest
+// precedence: built-in defaults, a config file named by -config-file (if given),
+// environment variables, and command-line flags. It defines the same flag names the
+// application has always accepted, so existing invocations keep working unchanged.
+//
+// It returns the resulting ConfigState, the resolved config file path (empty if none
+// was given - the caller needs this to support SIGHUP reloads), and whether -version
+// was passed.
+func Load() (state *ConfigState, configFilePath string, showVersion bool, err error) {
+	defaults := Defaults()
+
+	var flagFile string
+	flag.StringVar(&flagFile, "config-file", "", "Path to a YAML or JSON config file")
+
+	// Flags are parsed into their own copy, seeded with the defaults, so that after
+	// Parse we can use flag.Visit to tell exactly which ones the user supplied and
+	// layer only those over the file/env values below.
+	flagSettings := defaults
+	flag.IntVar(&flagSettings.Port, "port", defaults.Port, "API server port")
+	flag.StringVar(&flagSettings.Env, "env", defaults.Env, "Environment (development|staging|production)")
+	flag.StringVar(&flagSettings.DB.DSN, "db-dsn", "", "PostgreSQL DSN")
+	flag.IntVar(&flagSettings.DB.MaxOpenConns, "db-max-open-conns", defaults.DB.MaxOpenConns, "PostgreSQL max open connections")
+	flag.IntVar(&flagSettings.DB.MaxIdleConns, "db-max-idle-conns", defaults.DB.MaxIdleConns, "PostgreSQL max idle connections")
+	flag.StringVar(&flagSettings.DB.MaxIdleTime, "db-max-idle-time", defaults.DB.MaxIdleTime, "PostgreSQL max connection idle time")
+	flag.StringVar(&flagSettings.DB.QueryTimeout, "db-query-timeout", defaults.DB.QueryTimeout, "PostgreSQL query timeout")
+	flag.StringVar(&flagSettings.DB.ConnMaxLifetime, "db-conn-max-lifetime", defaults.DB.ConnMaxLifetime, "PostgreSQL max connection lifetime (0 = unlimited)")
+	flag.Float64Var(&flagSettings.Limiter.RPS, "limiter-rps", defaults.Limiter.RPS, "Rate limiter maximum requests per second")
+	flag.IntVar(&flagSettings.Limiter.Burst, "limiter-burst", defaults.Limiter.Burst, "Rate limiter maximum burst")
+	flag.BoolVar(&flagSettings.Limiter.Enabled, "limiter-enabled", defaults.Limiter.Enabled, "Enable rate limiter")
+	// Read the SMTP server configuration settings into the config struct, using the
+	// Mailtrap settings as the default values. IMPORTANT: If you're following along,
+	// make sure to replace the default values for smtp-username and smtp-password
+	// with your own Mailtrap credentials.
+	flag.StringVar(&flagSettings.SMTP.Host, "smtp-host", defaults.SMTP.Host, "SMTP host")
+	flag.IntVar(&flagSettings.SMTP.Port, "smtp-port", defaults.SMTP.Port, "SMTP port")
+	flag.StringVar(&flagSettings.SMTP.Username, "smtp-username", "", "SMTP username")
+	flag.StringVar(&flagSettings.SMTP.Password, "smtp-password", "", "SMTP password")
+	flag.StringVar(&flagSettings.SMTP.Sender, "smtp-sender", defaults.SMTP.Sender, "SMTP sender")
+
+	flag.Func("cors-trusted-origins", "Trusted CORS origins (space separated)", func(s string) error {
+		flagSettings.CORS.TrustedOrigins = strings.Fields(s)
+		return nil
+	})
+
+	flag.StringVar(&flagSettings.JWT.Secret, "jwt-secret", "", "JWT signing secret")
+
+	displayVersion := flag.Bool("version", false, "Display version and exit")
+
+	flag.Parse()
+
+	if *displayVersion {
+		return nil, "", true, nil
+	}
+
+	final := defaults
+
+	if flagFile != "" {
+		fs, err := loadFile(flagFile)
+		if err != nil {
+			return nil, "", false, err
+		}
+		fs.applyTo(&final)
+	}
+
+	applyEnv(&final)
+	applyExplicitFlags(&final, flagSettings)
+
+	return NewState(final), flagFile, false, nil
+}
+
+// applyEnv overlays the environment variables the application has always read.
+func applyEnv(s *Settings) {
+	if v := os.Getenv("GREENLIGHT_DB_DSN"); v != "" {
+		s.DB.DSN = v
+	}
+	if v := os.Getenv("SMTP_USERNAME"); v != "" {
+		s.SMTP.Username = v
+	}
+	if v := os.Getenv("SMTP_PASSWORD"); v != "" {
+		s.SMTP.Password = v
+	}
+	if v := os.Getenv("GREENLIGHT_JWT_SECRET"); v != "" {
+		s.JWT.Secret = v
+	}
+}
+
+// applyExplicitFlags overlays only the flags the user actually passed on the command
+// line, taking their values from flagSettings.
+func applyExplicitFlags(final *Settings, flagSettings Settings) {
+	set := make(map[string]bool)
+	flag.Visit(func(f *flag.Flag) { set[f.Name] = true })
+
+	if set["port"] {
+		final.Port = flagSettings.Port
+	}
+	if set["env"] {
+		final.Env = flagSettings.Env
+	}
+	if set["db-dsn"] {
+		final.DB.DSN = flagSettings.DB.DSN
+	}
+	if set["db-max-open-conns"] {
+		final.DB.MaxOpenConns = flagSettings.DB.MaxOpenConns
+	}
+	if set["db-max-idle-conns"] {
+		final.DB.MaxIdleConns = flagSettings.DB.MaxIdleConns
+	}
+	if set["db-max-idle-time"] {
+		final.DB.MaxIdleTime = flagSettings.DB.MaxIdleTime
+	}
+	if set["db-query-timeout"] {
+		final.DB.QueryTimeout = flagSettings.DB.QueryTimeout
+	}
+	if set["db-conn-max-lifetime"] {
+		final.DB.ConnMaxLifetime = flagSettings.DB.ConnMaxLifetime
+	}
+	if set["limiter-rps"] {
+		final.Limiter.RPS = flagSettings.Limiter.RPS
+	}
+	if set["limiter-burst"] {
+		final.Limiter.Burst = flagSettings.Limiter.Burst
+	}
+	if set["limiter-enabled"] {
+		final.Limiter.Enabled = flagSettings.Limiter.Enabled
+	}
+	if set["smtp-host"] {
+		final.SMTP.Host = flagSettings.SMTP.Host
+	}
+	if set["smtp-port"] {
+		final.SMTP.Port = flagSettings.SMTP.Port
+	}
+	if set["smtp-username"] {
+		final.SMTP.Username = flagSettings.SMTP.Username
+	}
+	if set["smtp-password"] {
+		final.SMTP.Password = flagSettings.SMTP.Password
+	}
+	if set["smtp-sender"] {
+		final.SMTP.Sender = flagSettings.SMTP.Sender
+	}
+	if set["cors-trusted-origins"] {
+		final.CORS.TrustedOrigins = flagSettings.CORS.TrustedOrigins
+	}
+	if set["jwt-secret"] {
+		final.JWT.Secret = flagSettings.JWT.Secret
+	}
+}
@@ -0,0 +1,137 @@
+// Package db wraps *sql.DB with lightweight per-query instrumentation: in-flight
+// query count, total queries issued, average query latency, and how many queries
+// ended with a context timeout. The raw connection pool stats that database/sql
+// already tracks remain available via the embedded *sql.DB's Stats() method.
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync/atomic"
+	"time"
+)
+
+// DB wraps a *sql.DB, recording metrics for every query issued through
+// QueryContext/ExecContext/QueryRowContext.
+type DB struct {
+	*sql.DB
+
+	inFlight     int64
+	totalQueries int64
+	totalLatency int64 // nanoseconds, summed across totalQueries
+	timeouts     int64
+}
+
+// Open wraps sql.Open, returning an instrumented DB.
+func Open(driverName, dsn string) (*DB, error) {
+	sqlDB, err := sql.Open(driverName, dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DB{DB: sqlDB}, nil
+}
+
+// track runs fn, recording the in-flight count, total count, latency, and (if fn
+// returns context.DeadlineExceeded) the timeout count.
+func (db *DB) track(fn func() error) error {
+	atomic.AddInt64(&db.inFlight, 1)
+	start := time.Now()
+
+	err := fn()
+
+	atomic.AddInt64(&db.inFlight, -1)
+	atomic.AddInt64(&db.totalQueries, 1)
+	atomic.AddInt64(&db.totalLatency, int64(time.Since(start)))
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		atomic.AddInt64(&db.timeouts, 1)
+	}
+
+	return err
+}
+
+// QueryContext wraps (*sql.DB).QueryContext, tracking metrics for the call.
+func (db *DB) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	var rows *sql.Rows
+
+	err := db.track(func() error {
+		var err error
+		rows, err = db.DB.QueryContext(ctx, query, args...)
+		return err
+	})
+
+	return rows, err
+}
+
+// QueryRowContext wraps (*sql.DB).QueryRowContext, tracking metrics for the call.
+// Note that, as with the standard library's method, any error is deferred until the
+// returned *Row is scanned; the timeout counter is updated at that point.
+func (db *DB) QueryRowContext(ctx context.Context, query string, args ...any) *Row {
+	var row *sql.Row
+
+	db.track(func() error {
+		row = db.DB.QueryRowContext(ctx, query, args...)
+		return nil
+	})
+
+	return &Row{row: row, db: db}
+}
+
+// Row wraps *sql.Row so that a context deadline surfaced at Scan time is still
+// recorded against the owning DB's timeout counter.
+type Row struct {
+	row *sql.Row
+	db  *DB
+}
+
+// Scan wraps (*sql.Row).Scan, recording a timeout if the deferred query error is
+// context.DeadlineExceeded.
+func (r *Row) Scan(dest ...any) error {
+	err := r.row.Scan(dest...)
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		atomic.AddInt64(&r.db.timeouts, 1)
+	}
+
+	return err
+}
+
+// ExecContext wraps (*sql.DB).ExecContext, tracking metrics for the call.
+func (db *DB) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	var result sql.Result
+
+	err := db.track(func() error {
+		var err error
+		result, err = db.DB.ExecContext(ctx, query, args...)
+		return err
+	})
+
+	return result, err
+}
+
+// Metrics is a point-in-time snapshot of the per-query counters tracked by DB.
+type Metrics struct {
+	InFlightQueries int64  `json:"in_flight_queries"`
+	TotalQueries    int64  `json:"total_queries"`
+	AverageLatency  string `json:"average_latency"`
+	TimeoutCount    int64  `json:"timeout_count"`
+}
+
+// Metrics returns a snapshot of the per-query metrics tracked since db was opened.
+func (db *DB) Metrics() Metrics {
+	total := atomic.LoadInt64(&db.totalQueries)
+
+	var avg time.Duration
+	if total > 0 {
+		avg = time.Duration(atomic.LoadInt64(&db.totalLatency) / total)
+	}
+
+	return Metrics{
+		InFlightQueries: atomic.LoadInt64(&db.inFlight),
+		TotalQueries:    total,
+		AverageLatency:  avg.String(),
+		TimeoutCount:    atomic.LoadInt64(&db.timeouts),
+	}
+}
@@ -1,12 +1,15 @@
 package data
 
 import (
-	"database/sql"
 	"errors"
+	"time"
+
+	"github.com/6ill/greenlight/internal/data/db"
 )
 
 var (
 	ErrRecordNotFound = errors.New("record not found")
+	ErrEditConflict   = errors.New("edit conflict")
 )
 
 // Create a Models struct which wraps the MovieModel. We'll add other models to this,
@@ -15,21 +18,18 @@ type Models struct {
 	Movies interface {
 		Insert(movie *Movie) error
 		Get(id int64) (*Movie, error)
+		GetAll(title string, genres []string, filters Filters) ([]*Movie, Metadata, error)
 		Update(movie *Movie) error
 		Delete(id int64) error
 	}
+	Users UserModel
 }
 
-func NewModels(db *sql.DB) Models {
+// NewModels returns a Models backed by conn, with every model method's query timeout
+// set to queryTimeout.
+func NewModels(conn *db.DB, queryTimeout time.Duration) Models {
 	return Models{
-		Movies: MovieModel{DB: db},
+		Movies: MovieModel{DB: conn, QueryTimeout: queryTimeout},
+		Users:  UserModel{DB: conn, QueryTimeout: queryTimeout},
 	}
 }
-
-// Create a helper function which returns a Models instance containing the mock models
-// only.
-func NewMockModels() Models {
-	return Models{
-		Movies: MockMovieModel{},
-	}
-}
\ No newline at end of file
@@ -2,9 +2,9 @@ package data
 
 import (
 	"context"
-	"database/sql"
 	"time"
 
+	"github.com/6ill/greenlight/internal/data/db"
 	"github.com/lib/pq"
 )
 
@@ -12,9 +12,11 @@ import (
 // "movies:read" and "movies:write") for a single user.
 type Permissions []string
 
-// Define the PermissionModel type.
+// Define the PermissionModel type. QueryTimeout bounds how long any single query is
+// allowed to run before its context is cancelled.
 type PermissionModel struct {
-	DB *sql.DB
+	DB           *db.DB
+	QueryTimeout time.Duration
 }
 
 // Add a helper method to check whether the Permissions slice contains a specific
@@ -40,7 +42,7 @@ func (m PermissionModel) GetAllForUser(userID int64) (Permissions, error) {
 		INNER JOIN permissions p ON up.permission_id = p.id
 		WHERE u.id = $1
 	`
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), m.QueryTimeout)
 	defer cancel()
 
 	rows, err := m.DB.QueryContext(ctx, query, userID)
@@ -76,7 +78,7 @@ func (m PermissionModel) AddForUser(userID int64, codes ...string) error {
 
 	args := []any{userID, pq.Array(codes)}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), m.QueryTimeout)
 	defer cancel()
 	
 	_, err := m.DB.ExecContext(ctx, query, args...)
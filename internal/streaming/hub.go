@@ -0,0 +1,108 @@
+// Package streaming implements a lightweight, in-process publish/subscribe hub used to
+// push real-time update events (for example, movie CRUD events) out to connected
+// clients such as WebSocket handlers.
+package streaming
+
+import "sync"
+
+// Event is a single notification published to the hub. Action is a short, human
+// readable description of what happened (e.g. "movie.created"); Payload is whatever
+// data the caller wants subscribers to receive, typically a *data.Movie.
+type Event struct {
+	Action  string   `json:"action"`
+	Genres  []string `json:"-"`
+	Payload any      `json:"payload"`
+}
+
+// Filter decides which published events a subscriber is interested in. A zero-value
+// Filter matches every event.
+type Filter struct {
+	// Genres, if non-empty, restricts delivery to events whose Genres intersect with
+	// this list.
+	Genres []string
+}
+
+func (f Filter) matches(e Event) bool {
+	if len(f.Genres) == 0 {
+		return true
+	}
+	for _, want := range f.Genres {
+		for _, got := range e.Genres {
+			if want == got {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// subscriber holds the delivery channel and filter for a single subscription.
+type subscriber struct {
+	filter Filter
+	events chan Event
+}
+
+// Hub fans published events out to subscribers, skipping subscribers whose filter
+// doesn't match and dropping the event for subscribers whose send buffer is full
+// rather than blocking the publisher. A full subscriber stays subscribed.
+type Hub struct {
+	mu          sync.Mutex
+	nextID      int64
+	subscribers map[int64]*subscriber
+}
+
+// NewHub returns an empty, ready to use Hub.
+func NewHub() *Hub {
+	return &Hub{subscribers: make(map[int64]*subscriber)}
+}
+
+// Subscribe registers a new subscriber matching filter and returns its id (for later
+// use with Unsubscribe) along with the channel events will be delivered on. bufferSize
+// sets the channel's capacity; once full, further events are dropped for this
+// subscriber rather than blocking Publish.
+func (h *Hub) Subscribe(filter Filter, bufferSize int) (id int64, events <-chan Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.nextID++
+	id = h.nextID
+
+	sub := &subscriber{filter: filter, events: make(chan Event, bufferSize)}
+	h.subscribers[id] = sub
+
+	return id, sub.events
+}
+
+// Unsubscribe removes a subscriber and closes its event channel. It is safe to call
+// more than once for the same id.
+func (h *Hub) Unsubscribe(id int64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	sub, ok := h.subscribers[id]
+	if !ok {
+		return
+	}
+
+	delete(h.subscribers, id)
+	close(sub.events)
+}
+
+// Publish delivers event to every subscriber whose filter matches it. Subscribers
+// whose buffer is currently full are skipped for this event rather than blocking the
+// publishing goroutine.
+func (h *Hub) Publish(event Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, sub := range h.subscribers {
+		if !sub.filter.matches(event) {
+			continue
+		}
+
+		select {
+		case sub.events <- event:
+		default:
+		}
+	}
+}